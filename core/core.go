@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+	"sync"
+
+	"gitlab.127-0-0-1.fr/vx3r/wg-gen-web/model"
+)
+
+var (
+	mu      sync.RWMutex
+	clients = map[string]model.Client{}
+	servers = map[string]model.Server{}
+)
+
+func clientKey(device, publicKey string) string {
+	return device + "|" + publicKey
+}
+
+// ReadClients returns every stored client, across every device.
+func ReadClients() ([]model.Client, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	out := make([]model.Client, 0, len(clients))
+	for _, c := range clients {
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// CreateClient stores a new client record, failing if one with the same
+// device and public key already exists.
+func CreateClient(client model.Client) (model.Client, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	key := clientKey(client.Device, client.PublicKey)
+	if _, exists := clients[key]; exists {
+		return model.Client{}, fmt.Errorf("client %s already exists on device %s", client.PublicKey, client.Device)
+	}
+	clients[key] = client
+	return client, nil
+}
+
+// UpdateClient replaces an existing client record matched by device and
+// public key, failing if no such record exists.
+func UpdateClient(client model.Client) (model.Client, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	key := clientKey(client.Device, client.PublicKey)
+	if _, exists := clients[key]; !exists {
+		return model.Client{}, fmt.Errorf("client %s not found on device %s", client.PublicKey, client.Device)
+	}
+	clients[key] = client
+	return client, nil
+}
+
+// UpdatePeer is the storage-backed fallback used when no live WireGuard
+// device is configured (see wgapi.Enabeled).
+func UpdatePeer(peer model.Peer, enable bool) (*model.Resp, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	key := clientKey(peer.Device, peer.PublicKey)
+	client, exists := clients[key]
+	if !exists {
+		client = model.Client{
+			Device:       peer.Device,
+			PublicKey:    peer.PublicKey,
+			PresharedKey: peer.PresharedKey,
+			AllowedIPs:   peer.AllowedIPs,
+		}
+	}
+	client.Enable = enable
+	clients[key] = client
+
+	return &model.Resp{OK: true}, nil
+}
+
+// ReadServer returns the interface configuration for device, zero-valued if
+// nothing has been written for it yet.
+func ReadServer(device string) (model.Server, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return servers[device], nil
+}
+
+// WriteServer persists the interface configuration for s.Device.
+func WriteServer(s model.Server) error {
+	mu.Lock()
+	defer mu.Unlock()
+	servers[s.Device] = s
+	return nil
+}
@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+// Client is a peer record persisted by core, carrying enough wg-quick
+// fields to regenerate its config and, in a multi-device setup, which
+// interface it belongs to.
+type Client struct {
+	Device              string   `json:"device"`
+	PublicKey           string   `json:"publicKey"`
+	PresharedKey        string   `json:"presharedKey,omitempty"`
+	AllowedIPs          []string `json:"allowedIPs,omitempty"`
+	Endpoint            string   `json:"endpoint,omitempty"`
+	PersistentKeepAlive string   `json:"persistentKeepAlive,omitempty"`
+	Name                string   `json:"name"`
+	Email               string   `json:"email"`
+	Enable              bool     `json:"enable"`
+}
+
+// Peer is the minimal shape needed to add or remove a peer on a device.
+type Peer struct {
+	Device       string   `json:"device"`
+	PublicKey    string   `json:"publicKey"`
+	PresharedKey string   `json:"presharedKey,omitempty"`
+	AllowedIPs   []string `json:"allowedIPs,omitempty"`
+}
+
+// Server is one WireGuard interface's configuration, handed out to newly
+// issued client configs for that device.
+type Server struct {
+	Device     string `json:"device"`
+	PrivateKey string `json:"privateKey"`
+	PublicKey  string `json:"publicKey"`
+	Address    string `json:"address"`
+	ListenPort int    `json:"listenPort"`
+	DNS        string `json:"dns,omitempty"`
+	MTU        int    `json:"mtu,omitempty"`
+	PostUp     string `json:"postUp,omitempty"`
+	PostDown   string `json:"postDown,omitempty"`
+}
+
+// Resp is a generic ok/fail response returned by mutating endpoints.
+type Resp struct {
+	OK bool `json:"ok"`
+}
+
+// InterfaceStatus mirrors a single device's live state for the UI.
+type InterfaceStatus struct {
+	Name          string `json:"name"`
+	DeviceType    string `json:"deviceType"`
+	ListenPort    int    `json:"listenPort"`
+	NumberOfPeers int    `json:"numberOfPeers"`
+	PublicKey     string `json:"publicKey"`
+}
+
+// ClientStatus is the live, per-peer view shown on the UI status page.
+type ClientStatus struct {
+	Device                string        `json:"device"`
+	PublicKey             string        `json:"publicKey"`
+	HasPresharedKey       bool          `json:"hasPresharedKey"`
+	ProtocolVersion       int           `json:"protocolVersion"`
+	Name                  string        `json:"name"`
+	Email                 string        `json:"email"`
+	Connected             bool          `json:"connected"`
+	AllowedIPs            []string      `json:"allowedIPs"`
+	Endpoint              string        `json:"endpoint"`
+	LastHandshake         time.Time     `json:"lastHandshake"`
+	LastHandshakeRelative time.Duration `json:"lastHandshakeRelative"`
+	ReceivedBytes         int           `json:"receivedBytes"`
+	TransmittedBytes      int           `json:"transmittedBytes"`
+	Latency               time.Duration `json:"latency"`
+	Jitter                time.Duration `json:"jitter"`
+	PacketLoss            float64       `json:"packetLoss"`
+}
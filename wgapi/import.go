@@ -0,0 +1,220 @@
+package wgapi
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gitlab.127-0-0-1.fr/vx3r/wg-gen-web/core"
+	"gitlab.127-0-0-1.fr/vx3r/wg-gen-web/model"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+// iniSection is one [Interface] or [Peer] block of a wg-quick config, with
+// keys lower-cased for case-insensitive lookup and values collected as a
+// list so repeated keys (e.g. several `AllowedIPs =` lines) and
+// comma-separated values on one line are handled the same way.
+type iniSection struct {
+	kind   string
+	values map[string][]string
+}
+
+func (s *iniSection) first(key string) string {
+	if v := s.values[key]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// parseWgConfig splits a wg-quick/wg INI-style config into its [Interface]
+// and [Peer] sections.
+func parseWgConfig(r io.Reader) ([]*iniSection, error) {
+	var sections []*iniSection
+	var current *iniSection
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = &iniSection{
+				kind:   strings.TrimSpace(strings.Trim(line, "[]")),
+				values: map[string][]string{},
+			}
+			sections = append(sections, current)
+			continue
+		}
+
+		if current == nil {
+			continue // stray line before the first section header
+		}
+
+		idx := strings.Index(line, "=")
+		if idx < 0 {
+			continue
+		}
+		key := strings.ToLower(strings.TrimSpace(line[:idx]))
+		for _, part := range strings.Split(line[idx+1:], ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				current.values[key] = append(current.values[key], part)
+			}
+		}
+	}
+
+	return sections, scanner.Err()
+}
+
+// ImportConfig parses a wg-quick/wg INI-style config (as produced by `wg
+// showconf` or hand-written) into the client and server records it
+// describes. The first [Interface] section becomes the server; every
+// [Peer] section becomes a client.
+func ImportConfig(r io.Reader) ([]model.Client, *model.Server, error) {
+	sections, err := parseWgConfig(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse config: %w", err)
+	}
+
+	var server *model.Server
+	var clients []model.Client
+
+	for _, section := range sections {
+		switch {
+		case strings.EqualFold(section.kind, "Interface") && server == nil:
+			server = &model.Server{
+				PrivateKey: section.first("privatekey"),
+				Address:    strings.Join(section.values["address"], ","),
+				DNS:        strings.Join(section.values["dns"], ","),
+				PostUp:     section.first("postup"),
+				PostDown:   section.first("postdown"),
+			}
+			if lp := section.first("listenport"); lp != "" {
+				if n, err := strconv.Atoi(lp); err == nil {
+					server.ListenPort = n
+				}
+			}
+			if mtu := section.first("mtu"); mtu != "" {
+				if n, err := strconv.Atoi(mtu); err == nil {
+					server.MTU = n
+				}
+			}
+			if server.PrivateKey != "" {
+				if key, err := wgtypes.ParseKey(server.PrivateKey); err == nil {
+					server.PublicKey = key.PublicKey().String()
+				}
+			}
+		case strings.EqualFold(section.kind, "Peer"):
+			client := model.Client{
+				PublicKey:           section.first("publickey"),
+				PresharedKey:        section.first("presharedkey"),
+				AllowedIPs:          section.values["allowedips"],
+				Endpoint:            section.first("endpoint"),
+				PersistentKeepAlive: section.first("persistentkeepalive"),
+			}
+			clients = append(clients, client)
+		}
+	}
+
+	return clients, server, nil
+}
+
+// readServerImport parses an uploaded wg-quick config and materializes its
+// peers and interface settings into the storage backend, against the
+// device selected via ?device= (or the sole registered device, see
+// resolveDevice). ?mode=strict (default) answers 409 listing every peer
+// whose public key already exists on that device; the other peers are still
+// created and reported, and the [Interface] settings are not written.
+// ?mode=merge updates the conflicting client record in place with the
+// imported peer settings, keeping its name, email and enable state, instead
+// of creating a duplicate.
+func readServerImport(c *gin.Context) {
+	mode := c.DefaultQuery("mode", "strict")
+	if mode != "strict" && mode != "merge" {
+		c.AbortWithStatusJSON(http.StatusBadRequest, "mode must be \"strict\" or \"merge\"")
+		return
+	}
+
+	device, err := resolveDevice(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	clients, server, err := ImportConfig(c.Request.Body)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+	for i := range clients {
+		clients[i].Device = device
+	}
+
+	existing, err := core.ReadClients()
+	if err != nil {
+		existing = nil
+	}
+
+	var conflicts []model.Client
+	var created []model.Client
+	for _, client := range clients {
+		var conflict *model.Client
+		for i := range existing {
+			if existing[i].Device == device && existing[i].PublicKey == client.PublicKey {
+				conflict = &existing[i]
+				break
+			}
+		}
+
+		var savedClient model.Client
+		if conflict != nil {
+			if mode == "strict" {
+				conflicts = append(conflicts, client)
+				continue
+			}
+			// wg-quick configs carry no Name/Email/Enable, so merge onto the
+			// existing record rather than blanking those admin-assigned
+			// fields out.
+			merged := *conflict
+			merged.PresharedKey = client.PresharedKey
+			merged.AllowedIPs = client.AllowedIPs
+			merged.Endpoint = client.Endpoint
+			merged.PersistentKeepAlive = client.PersistentKeepAlive
+			savedClient, err = core.UpdateClient(merged)
+		} else {
+			savedClient, err = core.CreateClient(client)
+		}
+		if err != nil {
+			log.WithFields(log.Fields{
+				"err":       err,
+				"device":    device,
+				"publicKey": client.PublicKey,
+			}).Error("failed to import client")
+			continue
+		}
+		created = append(created, savedClient)
+	}
+
+	if len(conflicts) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"conflicts": conflicts, "created": created})
+		return
+	}
+
+	if server != nil {
+		server.Device = device
+		if err := core.WriteServer(*server); err != nil {
+			log.WithFields(log.Fields{
+				"err":    err,
+				"device": device,
+			}).Error("failed to import server config")
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"device": device, "clients": created, "server": server})
+}
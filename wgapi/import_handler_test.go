@@ -0,0 +1,85 @@
+package wgapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.127-0-0-1.fr/vx3r/wg-gen-web/core"
+	"gitlab.127-0-0-1.fr/vx3r/wg-gen-web/model"
+)
+
+func TestReadServerImportMergePreservesAdminFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const device = "wgtest-import-merge"
+	const pubKey = "HIgo9xNzJMWLKASShjzL4hOVhqMhNOQNKhrU4LtBxVQ="
+
+	if _, err := core.CreateClient(model.Client{
+		Device:    device,
+		PublicKey: pubKey,
+		Name:      "alice",
+		Email:     "alice@example.com",
+		Enable:    true,
+	}); err != nil {
+		t.Fatalf("seed CreateClient: %v", err)
+	}
+
+	cfg := "[Peer]\nPublicKey = " + pubKey + "\nAllowedIPs = 10.10.0.9/32\n"
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/server/import?device="+device+"&mode=merge", strings.NewReader(cfg))
+
+	readServerImport(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	clients, err := core.ReadClients()
+	if err != nil {
+		t.Fatalf("ReadClients: %v", err)
+	}
+
+	var got *model.Client
+	for i := range clients {
+		if clients[i].Device == device && clients[i].PublicKey == pubKey {
+			got = &clients[i]
+		}
+	}
+	if got == nil {
+		t.Fatal("expected the existing client to still be present after merge")
+	}
+	if got.Name != "alice" || got.Email != "alice@example.com" || !got.Enable {
+		t.Fatalf("merge should preserve admin-assigned fields, got %+v", got)
+	}
+	if len(got.AllowedIPs) != 1 || got.AllowedIPs[0] != "10.10.0.9/32" {
+		t.Fatalf("merge should apply the imported AllowedIPs, got %v", got.AllowedIPs)
+	}
+}
+
+func TestReadServerImportStrictModeReportsConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const device = "wgtest-import-strict"
+	const pubKey = "4lQTaZUj0eCeKS+C/K5n3PFNzPGQUZZQKqJ3AqrOJHx="
+
+	if _, err := core.CreateClient(model.Client{Device: device, PublicKey: pubKey, Name: "bob"}); err != nil {
+		t.Fatalf("seed CreateClient: %v", err)
+	}
+
+	cfg := "[Peer]\nPublicKey = " + pubKey + "\nAllowedIPs = 10.10.0.10/32\n"
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/server/import?device="+device, strings.NewReader(cfg))
+
+	readServerImport(c)
+
+	if w.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d, body = %s", w.Code, http.StatusConflict, w.Body.String())
+	}
+}
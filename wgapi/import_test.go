@@ -0,0 +1,93 @@
+package wgapi
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleConfig = `
+# generated by wg-quick
+[Interface]
+PrivateKey = 4OQTaZUj0eCeKS+C/K5n3PFNzPGQUZZQKqJ3AqrOJHw=
+Address = 10.10.0.1/24, fd00::1/64
+ListenPort = 51820
+DNS = 1.1.1.1
+
+; first peer
+[Peer]
+PublicKey = HIgo9xNzJMWLKASShjzL4hOVhqMhNOQNKhrU4LtBxVQ=
+AllowedIPs = 10.10.0.2/32
+AllowedIPs = fd00::2/128
+Endpoint = 203.0.113.5:51820
+PersistentKeepalive = 25
+
+[Peer]
+publickey = 4lQTaZUj0eCeKS+C/K5n3PFNzPGQUZZQKqJ3AqrOJHx=
+allowedips = 10.10.0.3/32, fd00::3/128
+`
+
+func TestImportConfigParsesInterfaceAndPeers(t *testing.T) {
+	clients, server, err := ImportConfig(strings.NewReader(sampleConfig))
+	if err != nil {
+		t.Fatalf("ImportConfig returned error: %v", err)
+	}
+
+	if server == nil {
+		t.Fatal("expected a server to be parsed")
+	}
+	if server.Address != "10.10.0.1/24,fd00::1/64" {
+		t.Fatalf("unexpected address: %q", server.Address)
+	}
+	if server.ListenPort != 51820 {
+		t.Fatalf("unexpected listen port: %d", server.ListenPort)
+	}
+	if server.DNS != "1.1.1.1" {
+		t.Fatalf("unexpected DNS: %q", server.DNS)
+	}
+	if server.PublicKey == "" {
+		t.Fatal("expected PublicKey to be derived from PrivateKey")
+	}
+
+	if len(clients) != 2 {
+		t.Fatalf("expected 2 peers, got %d", len(clients))
+	}
+
+	first := clients[0]
+	if first.PublicKey != "HIgo9xNzJMWLKASShjzL4hOVhqMhNOQNKhrU4LtBxVQ=" {
+		t.Fatalf("unexpected public key: %q", first.PublicKey)
+	}
+	wantIPs := []string{"10.10.0.2/32", "fd00::2/128"}
+	if !reflect.DeepEqual(first.AllowedIPs, wantIPs) {
+		t.Fatalf("AllowedIPs = %v, want %v (repeated keys should accumulate)", first.AllowedIPs, wantIPs)
+	}
+	if first.Endpoint != "203.0.113.5:51820" {
+		t.Fatalf("unexpected endpoint: %q", first.Endpoint)
+	}
+
+	second := clients[1]
+	wantIPs2 := []string{"10.10.0.3/32", "fd00::3/128"}
+	if !reflect.DeepEqual(second.AllowedIPs, wantIPs2) {
+		t.Fatalf("AllowedIPs = %v, want %v (comma-separated values on one line should split)", second.AllowedIPs, wantIPs2)
+	}
+}
+
+func TestImportConfigIgnoresCommentsAndStrayLines(t *testing.T) {
+	const cfg = `
+# a leading comment before any section
+PrivateKey = shouldbeignored
+[Interface]
+; inline comment style
+PrivateKey = 4OQTaZUj0eCeKS+C/K5n3PFNzPGQUZZQKqJ3AqrOJHw=
+`
+	clients, server, err := ImportConfig(strings.NewReader(cfg))
+	if err != nil {
+		t.Fatalf("ImportConfig returned error: %v", err)
+	}
+	if len(clients) != 0 {
+		t.Fatalf("expected no peers, got %d", len(clients))
+	}
+	if server.PrivateKey != "4OQTaZUj0eCeKS+C/K5n3PFNzPGQUZZQKqJ3AqrOJHw=" {
+		t.Fatalf("stray line before the section header should have been ignored, got PrivateKey=%q", server.PrivateKey)
+	}
+}
@@ -0,0 +1,133 @@
+package wgapi
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gitlab.127-0-0-1.fr/vx3r/wg-gen-web/core"
+	"gitlab.127-0-0-1.fr/vx3r/wg-gen-web/model"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+// KeyRotationDiff describes the effect of rotating a device's private key:
+// the public key every newly issued client config will reference, and the
+// clients whose existing config embeds the outgoing public key.
+type KeyRotationDiff struct {
+	Device          string   `json:"device"`
+	OldPublicKey    string   `json:"oldPublicKey"`
+	NewPublicKey    string   `json:"newPublicKey"`
+	AffectedClients []string `json:"affectedClients"`
+}
+
+// buildKeyRotationDiff filters clients down to the ones that belong to
+// device, since rotating one interface's key has no effect on peers
+// configured on any other interface.
+func buildKeyRotationDiff(device, oldPublicKey, newPublicKey string, clients []model.Client) KeyRotationDiff {
+	affected := make([]string, 0, len(clients))
+	for _, client := range clients {
+		if client.Device == device {
+			affected = append(affected, client.Name)
+		}
+	}
+
+	return KeyRotationDiff{
+		Device:          device,
+		OldPublicKey:    oldPublicKey,
+		NewPublicKey:    newPublicKey,
+		AffectedClients: affected,
+	}
+}
+
+// resolveDevice returns the ?device= query param, or the sole registered
+// device when there is exactly one, mirroring updatePeerCall's fallback.
+func resolveDevice(c *gin.Context) (string, error) {
+	if device := c.Query("device"); device != "" {
+		return device, nil
+	}
+
+	names, err := Devices()
+	if err != nil {
+		return "", err
+	}
+	if len(names) != 1 {
+		return "", Error{Message: "device query param is required when more than one WireGuard interface is configured"}
+	}
+	return names[0], nil
+}
+
+// readServerKeypairRotate generates a new keypair for a device and, unless
+// ?dryRun=true, pushes the new private key to the kernel and updates the
+// stored server record so newly issued client configs use the new public
+// key. Dry-run returns the diff without applying anything.
+func readServerKeypairRotate(c *gin.Context) {
+	device, err := resolveDevice(c)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s, err := Get(device)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	newKey, err := s.GenerateKeyPair()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	server, err := core.ReadServer(device)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	clients, err := core.ReadClients()
+	if err != nil {
+		clients = nil
+	}
+
+	diff := buildKeyRotationDiff(device, server.PublicKey, newKey.PublicKey().String(), clients)
+
+	if c.Query("dryRun") == "true" {
+		c.JSON(http.StatusOK, diff)
+		return
+	}
+
+	// Push to the kernel first: if this fails, nothing has been persisted
+	// and the interface keeps running its current key, so the operation is
+	// a clean no-op from the outside.
+	if err := s.RotatePrivateKey(newKey); err != nil {
+		log.WithFields(log.Fields{
+			"err":    err,
+			"device": device,
+		}).Error("failed to push rotated private key to kernel")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	oldServer := server
+	server.Device = device
+	server.PrivateKey = newKey.String()
+	server.PublicKey = diff.NewPublicKey
+	if err := core.WriteServer(server); err != nil {
+		// The kernel already has the new key; roll it back so the stored
+		// record and the live interface don't disagree about which key is
+		// current.
+		if oldKey, perr := wgtypes.ParseKey(oldServer.PrivateKey); perr == nil {
+			if rerr := s.RotatePrivateKey(oldKey); rerr != nil {
+				log.WithFields(log.Fields{
+					"err":    rerr,
+					"device": device,
+				}).Error("failed to roll back private key after storage write failure")
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
@@ -0,0 +1,34 @@
+package wgapi
+
+import (
+	"reflect"
+	"testing"
+
+	"gitlab.127-0-0-1.fr/vx3r/wg-gen-web/model"
+)
+
+func TestBuildKeyRotationDiffFiltersByDevice(t *testing.T) {
+	clients := []model.Client{
+		{Device: "wg0", Name: "alice"},
+		{Device: "wg1", Name: "bob"},
+		{Device: "wg0", Name: "carol"},
+	}
+
+	diff := buildKeyRotationDiff("wg0", "oldpub", "newpub", clients)
+
+	if diff.Device != "wg0" || diff.OldPublicKey != "oldpub" || diff.NewPublicKey != "newpub" {
+		t.Fatalf("unexpected diff metadata: %+v", diff)
+	}
+
+	want := []string{"alice", "carol"}
+	if !reflect.DeepEqual(diff.AffectedClients, want) {
+		t.Fatalf("AffectedClients = %v, want %v", diff.AffectedClients, want)
+	}
+}
+
+func TestBuildKeyRotationDiffNoClients(t *testing.T) {
+	diff := buildKeyRotationDiff("wg0", "oldpub", "newpub", nil)
+	if len(diff.AffectedClients) != 0 {
+		t.Fatalf("expected no affected clients, got %v", diff.AffectedClients)
+	}
+}
@@ -0,0 +1,118 @@
+package wgapi
+
+import (
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"gitlab.127-0-0-1.fr/vx3r/wg-gen-web/core"
+)
+
+var (
+	metricsRegistry = prometheus.NewRegistry()
+	metricsOnce     sync.Once
+)
+
+// deviceCollector implements prometheus.Collector, pulling peer and
+// interface stats straight from wgctrl on every scrape so exposed values
+// are never stale and no state is cached between requests.
+type deviceCollector struct {
+	peerRxBytes         *prometheus.Desc
+	peerTxBytes         *prometheus.Desc
+	peerLastHandshake   *prometheus.Desc
+	peerConnected       *prometheus.Desc
+	interfacePeers      *prometheus.Desc
+	interfaceListenPort *prometheus.Desc
+}
+
+func newDeviceCollector() *deviceCollector {
+	peerLabels := []string{"device", "public_key", "name", "email"}
+	return &deviceCollector{
+		peerRxBytes:         prometheus.NewDesc("wg_peer_rx_bytes_total", "Total bytes received from the peer.", peerLabels, nil),
+		peerTxBytes:         prometheus.NewDesc("wg_peer_tx_bytes_total", "Total bytes sent to the peer.", peerLabels, nil),
+		peerLastHandshake:   prometheus.NewDesc("wg_peer_last_handshake_seconds", "Unix time of the last handshake with the peer.", peerLabels, nil),
+		peerConnected:       prometheus.NewDesc("wg_peer_connected", "1 if the peer is considered connected, 0 otherwise.", peerLabels, nil),
+		interfacePeers:      prometheus.NewDesc("wg_interface_peers", "Number of peers configured on the interface.", []string{"device"}, nil),
+		interfaceListenPort: prometheus.NewDesc("wg_interface_listen_port", "UDP port the interface listens on.", []string{"device"}, nil),
+	}
+}
+
+func (d *deviceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- d.peerRxBytes
+	ch <- d.peerTxBytes
+	ch <- d.peerLastHandshake
+	ch <- d.peerConnected
+	ch <- d.interfacePeers
+	ch <- d.interfaceListenPort
+}
+
+func (d *deviceCollector) Collect(ch chan<- prometheus.Metric) {
+	names, err := Devices()
+	if err != nil {
+		return
+	}
+
+	clients, err := core.ReadClients()
+	withClientDetails := err == nil
+
+	for _, device := range names {
+		s, err := Get(device)
+		if err != nil {
+			continue
+		}
+
+		if info, err := s.GetDeviceInfo(); err == nil {
+			ch <- prometheus.MustNewConstMetric(d.interfacePeers, prometheus.GaugeValue, float64(info.Device.NumPeers), device)
+			ch <- prometheus.MustNewConstMetric(d.interfaceListenPort, prometheus.GaugeValue, float64(info.Device.ListenPort), device)
+		}
+
+		peers, err := s.ListPeers()
+		if err != nil {
+			continue
+		}
+
+		for _, peer := range peers.Peers {
+			var name, email string
+			if withClientDetails {
+				for _, client := range clients {
+					if client.Device == device && client.PublicKey == peer.PublicKey {
+						name, email = client.Name, client.Email
+						break
+					}
+				}
+			}
+
+			labels := []string{device, peer.PublicKey, name, email}
+			ch <- prometheus.MustNewConstMetric(d.peerRxBytes, prometheus.CounterValue, float64(peer.ReceiveBytes), labels...)
+			ch <- prometheus.MustNewConstMetric(d.peerTxBytes, prometheus.CounterValue, float64(peer.TransmitBytes), labels...)
+			ch <- prometheus.MustNewConstMetric(d.peerLastHandshake, prometheus.GaugeValue, float64(peer.LastHandshake.Unix()), labels...)
+
+			connected := 0.0
+			if isConnected, _, _, _ := peerLiveness(device, peer.PublicKey, peer.LastHandshake); isConnected {
+				connected = 1
+			}
+			ch <- prometheus.MustNewConstMetric(d.peerConnected, prometheus.GaugeValue, connected, labels...)
+		}
+	}
+}
+
+// readMetrics serves Prometheus-format metrics for every registered device,
+// gated behind the bearer token configured via WG_METRICS_TOKEN. When the
+// env var is unset, the endpoint is open, matching the rest of /status.
+func readMetrics(c *gin.Context) {
+	if token := os.Getenv("WG_METRICS_TOKEN"); token != "" {
+		if c.GetHeader("Authorization") != "Bearer "+token {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+	}
+
+	metricsOnce.Do(func() {
+		metricsRegistry.MustRegister(newDeviceCollector())
+	})
+
+	promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}).ServeHTTP(c.Writer, c.Request)
+}
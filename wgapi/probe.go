@@ -0,0 +1,404 @@
+package wgapi
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.zx2c4.com/wireguard/wgctrl/wgtypes"
+)
+
+const (
+	defaultProbeInterval = 30 * time.Second
+	defaultProbeTimeout  = 2 * time.Second
+	probeHistorySize     = 20
+)
+
+// probeSample is a single liveness probe result.
+type probeSample struct {
+	rtt     time.Duration
+	success bool
+}
+
+// probeStats keeps a ring buffer of the most recent probe results for a peer.
+type probeStats struct {
+	mu      sync.Mutex
+	samples []probeSample
+}
+
+func (p *probeStats) record(s probeSample) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.samples = append(p.samples, s)
+	if len(p.samples) > probeHistorySize {
+		p.samples = p.samples[len(p.samples)-probeHistorySize:]
+	}
+}
+
+// summary reduces the ring buffer to an average latency, jitter (RTT standard
+// deviation) and loss ratio over the retained samples.
+func (p *probeStats) summary() (latency, jitter time.Duration, loss float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.samples) == 0 {
+		return 0, 0, 0
+	}
+
+	var rtts []time.Duration
+	var failed int
+	for _, s := range p.samples {
+		if s.success {
+			rtts = append(rtts, s.rtt)
+		} else {
+			failed++
+		}
+	}
+	loss = float64(failed) / float64(len(p.samples))
+
+	if len(rtts) == 0 {
+		return 0, 0, loss
+	}
+
+	var sum time.Duration
+	for _, r := range rtts {
+		sum += r
+	}
+	latency = sum / time.Duration(len(rtts))
+
+	var variance float64
+	for _, r := range rtts {
+		d := float64(r - latency)
+		variance += d * d
+	}
+	jitter = time.Duration(math.Sqrt(variance / float64(len(rtts))))
+
+	return latency, jitter, loss
+}
+
+var (
+	proberMu sync.Mutex
+	proberDB = map[string]map[wgtypes.Key]*probeStats{}
+	proberOK bool
+)
+
+func statsFor(device string, pubKey wgtypes.Key) *probeStats {
+	proberMu.Lock()
+	defer proberMu.Unlock()
+
+	peers, ok := proberDB[device]
+	if !ok {
+		peers = map[wgtypes.Key]*probeStats{}
+		proberDB[device] = peers
+	}
+	s, ok := peers[pubKey]
+	if !ok {
+		s = &probeStats{}
+		peers[pubKey] = s
+	}
+	return s
+}
+
+// probeSummary returns the latest latency/jitter/loss reduction for a peer.
+// ok is false when no probe has completed yet, in which case the caller
+// should fall back to the handshake-age heuristic.
+func probeSummary(device string, pubKey wgtypes.Key) (latency, jitter time.Duration, loss float64, ok bool) {
+	proberMu.Lock()
+	peers, found := proberDB[device]
+	var s *probeStats
+	if found {
+		s = peers[pubKey]
+	}
+	proberMu.Unlock()
+
+	if s == nil {
+		return 0, 0, 0, false
+	}
+
+	s.mu.Lock()
+	hasSamples := len(s.samples) > 0
+	s.mu.Unlock()
+	if !hasSamples {
+		return 0, 0, 0, false
+	}
+
+	latency, jitter, loss = s.summary()
+	return latency, jitter, loss, true
+}
+
+// peerLiveness is the single liveness signal shared by the /status/clients
+// endpoints and the Prometheus collector: prefer active probe results, and
+// fall back to the handshake-age heuristic when a peer hasn't been probed
+// yet (e.g. ICMP is unprivileged, or the prober hasn't ticked yet).
+func peerLiveness(device, publicKey string, lastHandshake time.Time) (connected bool, latency, jitter time.Duration, loss float64) {
+	connected = time.Since(lastHandshake).Minutes() < 3
+
+	pubKey, err := wgtypes.ParseKey(publicKey)
+	if err != nil {
+		return connected, 0, 0, 0
+	}
+
+	if l, j, lo, ok := probeSummary(device, pubKey); ok {
+		latency, jitter, loss = l, j, lo
+		connected = lo < 1
+	}
+	return connected, latency, jitter, loss
+}
+
+func probeInterval() time.Duration {
+	return envDuration("WG_PROBE_INTERVAL", defaultProbeInterval)
+}
+
+func probeTimeout() time.Duration {
+	return envDuration("WG_PROBE_TIMEOUT", defaultProbeTimeout)
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+			"env": name,
+		}).Warn("invalid duration, using default")
+		return fallback
+	}
+	return d
+}
+
+// pickProbeTarget selects the liveness probe target for a peer according to
+// WG_PROBE_TARGET_STRATEGY (default "first-allowed-ip": the first AllowedIP
+// that isn't a default route).
+func pickProbeTarget(peer *Peer) (net.IP, error) {
+	strategy := os.Getenv("WG_PROBE_TARGET_STRATEGY")
+	if strategy == "" {
+		strategy = "first-allowed-ip"
+	}
+
+	switch strategy {
+	case "endpoint":
+		host, _, err := net.SplitHostPort(peer.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse endpoint %q: %w", peer.Endpoint, err)
+		}
+		ip := net.ParseIP(host)
+		if ip == nil {
+			return nil, fmt.Errorf("endpoint %q has no usable IP", peer.Endpoint)
+		}
+		return ip, nil
+	case "first-allowed-ip":
+		for _, cidr := range peer.AllowedIPs {
+			ip, ipNet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				continue
+			}
+			ones, bits := ipNet.Mask.Size()
+			if ones == 0 && bits != 0 {
+				continue // skip default routes such as 0.0.0.0/0 or ::/0
+			}
+			return ip, nil
+		}
+		return nil, fmt.Errorf("peer %s has no non-default AllowedIPs to probe", peer.PublicKey)
+	default:
+		return nil, fmt.Errorf("unknown WG_PROBE_TARGET_STRATEGY %q", strategy)
+	}
+}
+
+// probePeer sends a single ICMP echo to target and reports the round-trip
+// time. When the process has no permission to open a raw ICMP socket,
+// privileged is false and the caller should fall back to the handshake-age
+// heuristic.
+func probePeer(target net.IP, timeout time.Duration) (rtt time.Duration, reachable bool, privileged bool) {
+	conn, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		return 0, false, false
+	}
+	defer conn.Close()
+
+	wantID := os.Getpid() & 0xffff
+	wantSeq := 1
+	msg := icmp.Message{
+		Type: ipv4.ICMPTypeEcho,
+		Code: 0,
+		Body: &icmp.Echo{
+			ID:   wantID,
+			Seq:  wantSeq,
+			Data: []byte("wg-gen-web"),
+		},
+	}
+	wb, err := msg.Marshal(nil)
+	if err != nil {
+		return 0, false, true
+	}
+
+	start := time.Now()
+	if err := conn.SetDeadline(start.Add(timeout)); err != nil {
+		return 0, false, true
+	}
+	if _, err := conn.WriteTo(wb, &net.IPAddr{IP: target}); err != nil {
+		return 0, false, true
+	}
+
+	rb := make([]byte, 1500)
+	for {
+		n, peerAddr, err := conn.ReadFrom(rb)
+		if err != nil {
+			return 0, false, true
+		}
+		if !sameHost(peerAddr, target) {
+			continue // ICMP traffic unrelated to this probe, e.g. another process pinging
+		}
+
+		rm, err := icmp.ParseMessage(1, rb[:n])
+		if err != nil {
+			continue
+		}
+		if rm.Type != ipv4.ICMPTypeEchoReply {
+			continue
+		}
+		echo, ok := rm.Body.(*icmp.Echo)
+		if !ok || echo.ID != wantID || echo.Seq != wantSeq {
+			continue // reply to a different probe
+		}
+
+		return time.Since(start), true, true
+	}
+}
+
+// sameHost reports whether addr (as returned by a raw ICMP socket's
+// ReadFrom) is the host we sent the echo request to.
+func sameHost(addr net.Addr, target net.IP) bool {
+	ipAddr, ok := addr.(*net.IPAddr)
+	if !ok {
+		return false
+	}
+	return ipAddr.IP.Equal(target)
+}
+
+// probeDevice probes every peer on device once and records the result in
+// each peer's probeStats ring buffer.
+func probeDevice(device string) {
+	s, err := Get(device)
+	if err != nil {
+		return
+	}
+	res, err := s.ListPeers()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":    err,
+			"device": device,
+		}).Warn("liveness probe: could not list peers")
+		return
+	}
+
+	timeout := probeTimeout()
+	for _, peer := range res.Peers {
+		target, err := pickProbeTarget(peer)
+		if err != nil {
+			continue
+		}
+
+		rtt, reachable, privileged := probePeer(target, timeout)
+		if !privileged {
+			// No permission to open a raw ICMP socket: fall back to the
+			// handshake-age heuristic, handled by peerLiveness().
+			continue
+		}
+
+		pubKey, err := wgtypes.ParseKey(peer.PublicKey)
+		if err != nil {
+			continue
+		}
+		statsFor(device, pubKey).record(probeSample{rtt: rtt, success: reachable})
+	}
+}
+
+// startProber launches the background goroutine that periodically probes
+// every peer on every registered device. It is safe to call multiple times;
+// only the first call starts the loop.
+func startProber() {
+	proberMu.Lock()
+	if proberOK {
+		proberMu.Unlock()
+		return
+	}
+	proberOK = true
+	proberMu.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(probeInterval())
+		defer ticker.Stop()
+
+		for range ticker.C {
+			names, err := Devices()
+			if err != nil {
+				continue
+			}
+			for _, device := range names {
+				probeDevice(device)
+			}
+		}
+	}()
+}
+
+// probeResult is the JSON body returned by the on-demand probe route.
+type probeResult struct {
+	PublicKey         string        `json:"publicKey"`
+	Target            string        `json:"target"`
+	Reachable         bool          `json:"reachable"`
+	RoundTripTime     time.Duration `json:"roundTripTime"`
+	RoundTripTimeText string        `json:"roundTripTimeText"`
+}
+
+// triggerProbe runs an immediate, synchronous probe against a single peer
+// and records the sample alongside the background probes.
+func triggerProbe(device, publicKey string) (*probeResult, error) {
+	s, err := Get(device)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := s.ListPeers()
+	if err != nil {
+		return nil, fmt.Errorf("could not list peers: %w", err)
+	}
+
+	for _, peer := range res.Peers {
+		if peer.PublicKey != publicKey {
+			continue
+		}
+
+		target, err := pickProbeTarget(peer)
+		if err != nil {
+			return nil, err
+		}
+
+		rtt, reachable, privileged := probePeer(target, probeTimeout())
+		if privileged {
+			if pubKey, err := wgtypes.ParseKey(peer.PublicKey); err == nil {
+				statsFor(device, pubKey).record(probeSample{rtt: rtt, success: reachable})
+			}
+		}
+
+		return &probeResult{
+			PublicKey:         peer.PublicKey,
+			Target:            target.String(),
+			Reachable:         reachable,
+			RoundTripTime:     rtt,
+			RoundTripTimeText: strconv.FormatFloat(rtt.Seconds()*1000, 'f', 2, 64) + "ms",
+		}, nil
+	}
+
+	return nil, InvalidParams("unknown public key", nil)
+}
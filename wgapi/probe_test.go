@@ -0,0 +1,63 @@
+package wgapi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProbeStatsSummary(t *testing.T) {
+	p := &probeStats{}
+	p.record(probeSample{rtt: 10 * time.Millisecond, success: true})
+	p.record(probeSample{rtt: 20 * time.Millisecond, success: true})
+	p.record(probeSample{success: false})
+	p.record(probeSample{rtt: 30 * time.Millisecond, success: true})
+
+	latency, jitter, loss := p.summary()
+
+	if want := 20 * time.Millisecond; latency != want {
+		t.Fatalf("latency = %v, want %v", latency, want)
+	}
+	if loss != 0.25 {
+		t.Fatalf("loss = %v, want 0.25", loss)
+	}
+	// stddev of [10, 20, 30]ms around a 20ms mean is ~8.16ms.
+	if jitter < 8*time.Millisecond || jitter > 9*time.Millisecond {
+		t.Fatalf("jitter = %v, want ~8.16ms", jitter)
+	}
+}
+
+func TestProbeStatsSummaryEmpty(t *testing.T) {
+	p := &probeStats{}
+	latency, jitter, loss := p.summary()
+	if latency != 0 || jitter != 0 || loss != 0 {
+		t.Fatalf("expected zero summary for no samples, got latency=%v jitter=%v loss=%v", latency, jitter, loss)
+	}
+}
+
+func TestProbeStatsSummaryAllFailed(t *testing.T) {
+	p := &probeStats{}
+	p.record(probeSample{success: false})
+	p.record(probeSample{success: false})
+
+	latency, jitter, loss := p.summary()
+	if latency != 0 || jitter != 0 {
+		t.Fatalf("expected zero latency/jitter with no successful samples, got latency=%v jitter=%v", latency, jitter)
+	}
+	if loss != 1 {
+		t.Fatalf("loss = %v, want 1", loss)
+	}
+}
+
+func TestProbeStatsRingBufferCaps(t *testing.T) {
+	p := &probeStats{}
+	for i := 0; i < probeHistorySize+5; i++ {
+		p.record(probeSample{rtt: time.Duration(i) * time.Millisecond, success: true})
+	}
+	if len(p.samples) != probeHistorySize {
+		t.Fatalf("len(samples) = %d, want %d", len(p.samples), probeHistorySize)
+	}
+	// the oldest 5 samples (0..4ms) should have been evicted.
+	if p.samples[0].rtt != 5*time.Millisecond {
+		t.Fatalf("samples[0].rtt = %v, want 5ms", p.samples[0].rtt)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,7 +13,9 @@ import (
 )
 
 var once sync.Once
-var server *Server
+var registryMu sync.RWMutex
+var registry map[string]*Server
+var registryOrder []string
 
 type Server struct {
 	wg         *wgctrl.Client
@@ -21,29 +24,124 @@ type Server struct {
 
 // NewServer initializes a Server with a WireGuard
 func NewServer(wg *wgctrl.Client, deviceName string) (*Server, error) {
-	if server == nil {
-		return &Server{wg: wg, deviceName: deviceName}, nil
+	if s, ok := lookup(deviceName); ok {
+		return s, nil
 	}
-	return server, nil
+	return &Server{wg: wg, deviceName: deviceName}, nil
 }
 
+// New builds (once) the registry of Servers, one per configured WireGuard
+// interface, and returns the Server for WG_DEVICE_NAME, or for the first
+// registered device when that is unset. Devices are discovered from the comma-separated
+// WG_DEVICE_NAMES env var, falling back to WG_DEVICE_NAME for single-device
+// setups, or by enumerating every device wgctrl can see on the host.
 func New() (*Server, error) {
-	if server == nil {
-		client, err := wgctrl.New()
-		if err != nil {
-			return nil, err
+	if err := initRegistry(); err != nil {
+		return nil, err
+	}
+
+	deviceName := os.Getenv("WG_DEVICE_NAME")
+	if deviceName == "" {
+		names := deviceNames()
+		if len(names) == 0 {
+			return nil, Error{Message: "no WireGuard devices found."}
+		}
+		deviceName = names[0]
+	}
+
+	s, ok := lookup(deviceName)
+	if !ok {
+		return nil, Error{Message: fmt.Sprintf("unknown device %q", deviceName)}
+	}
+	return s, nil
+}
+
+// Get returns the Server registered for deviceName, initializing the
+// registry on first use.
+func Get(deviceName string) (*Server, error) {
+	if err := initRegistry(); err != nil {
+		return nil, err
+	}
+
+	s, ok := lookup(deviceName)
+	if !ok {
+		return nil, Error{Message: fmt.Sprintf("unknown device %q", deviceName)}
+	}
+	return s, nil
+}
+
+// Devices returns the names of every registered device, sorted by
+// discovery order.
+func Devices() ([]string, error) {
+	if err := initRegistry(); err != nil {
+		return nil, err
+	}
+	return deviceNames(), nil
+}
+
+func lookup(deviceName string) (*Server, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	s, ok := registry[deviceName]
+	return s, ok
+}
+
+func deviceNames() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, len(registryOrder))
+	copy(names, registryOrder)
+	return names
+}
+
+func initRegistry() error {
+	var err error
+	once.Do(func() {
+		client, clientErr := wgctrl.New()
+		if clientErr != nil {
+			err = clientErr
+			return
 		}
-		deviceName := os.Getenv("WG_DEVICE_NAME")
-		if deviceName == "" {
-			return nil, Error{Message: "env var WG_DEVICE_NAME not set."}
+
+		var names []string
+		if raw := os.Getenv("WG_DEVICE_NAMES"); raw != "" {
+			for _, n := range strings.Split(raw, ",") {
+				if n = strings.TrimSpace(n); n != "" {
+					names = append(names, n)
+				}
+			}
+		} else if single := os.Getenv("WG_DEVICE_NAME"); single != "" {
+			names = []string{single}
+		} else {
+			devices, devErr := client.Devices()
+			if devErr != nil {
+				err = devErr
+				return
+			}
+			for _, d := range devices {
+				names = append(names, d.Name)
+			}
 		}
-		device, err := client.Device(deviceName)
-		if err != nil {
-			return nil, err
+
+		if len(names) == 0 {
+			err = Error{Message: "no WireGuard devices configured, set WG_DEVICE_NAME or WG_DEVICE_NAMES."}
+			return
 		}
-		return &Server{wg: client, deviceName: device.Name}, nil
-	}
-	return server, nil
+
+		registry = make(map[string]*Server, len(names))
+		registryOrder = make([]string, 0, len(names))
+		for _, name := range names {
+			if _, derr := client.Device(name); derr != nil {
+				err = fmt.Errorf("could not get WireGuard device %q: %w", name, derr)
+				return
+			}
+			registryOrder = append(registryOrder, name)
+			registry[name] = &Server{wg: client, deviceName: name}
+		}
+
+		startProber()
+	})
+	return err
 }
 
 // GetDeviceInfo returns information such as the public key and type of
@@ -66,6 +164,22 @@ func (s *Server) GetDeviceInfo() (*GetDeviceInfoResponse, error) {
 	}, nil
 }
 
+// GenerateKeyPair creates a new WireGuard private key. It does not touch the
+// kernel interface; call RotatePrivateKey to apply it.
+func (s *Server) GenerateKeyPair() (wgtypes.Key, error) {
+	return wgtypes.GeneratePrivateKey()
+}
+
+// RotatePrivateKey pushes newKey to the kernel as the interface's private
+// key, replacing whatever key the device was configured with.
+func (s *Server) RotatePrivateKey(newKey wgtypes.Key) error {
+	err := s.wg.ConfigureDevice(s.deviceName, wgtypes.Config{PrivateKey: &newKey})
+	if err != nil {
+		return fmt.Errorf("could not rotate private key: %w", err)
+	}
+	return nil
+}
+
 func validateListPeersRequest(req *ListPeersRequest) error {
 	if req == nil {
 		return InvalidParams("request body required", nil)
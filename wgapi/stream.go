@@ -0,0 +1,160 @@
+package wgapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gitlab.127-0-0-1.fr/vx3r/wg-gen-web/model"
+)
+
+const defaultStreamInterval = 5 * time.Second
+
+// streamHub fans out client-status snapshots to every subscribed SSE
+// connection for one device, so the periodic snapshot is taken by a single
+// goroutine calling clientStatus() (and therefore wgctrl's Device()) once
+// per tick regardless of how many browsers are watching. Each new
+// subscription and each UpdatePeer still take one snapshot of their own.
+type streamHub struct {
+	mu   sync.Mutex
+	subs map[chan []*model.ClientStatus]struct{}
+}
+
+func newStreamHub() *streamHub {
+	return &streamHub{subs: map[chan []*model.ClientStatus]struct{}{}}
+}
+
+func (h *streamHub) subscribe() chan []*model.ClientStatus {
+	ch := make(chan []*model.ClientStatus, 1)
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *streamHub) unsubscribe(ch chan []*model.ClientStatus) {
+	h.mu.Lock()
+	delete(h.subs, ch)
+	h.mu.Unlock()
+	close(ch)
+}
+
+func (h *streamHub) broadcast(status []*model.ClientStatus) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subs {
+		select {
+		case ch <- status:
+		default:
+			// slow subscriber whose buffer still holds an undelivered
+			// snapshot: skip this one for it rather than block the hub
+		}
+	}
+}
+
+var (
+	hubsMu sync.Mutex
+	hubs   = map[string]*streamHub{}
+)
+
+// hubFor returns the streamHub for device, starting its polling goroutine
+// the first time the device is streamed.
+func hubFor(device string) *streamHub {
+	hubsMu.Lock()
+	defer hubsMu.Unlock()
+
+	h, ok := hubs[device]
+	if !ok {
+		h = newStreamHub()
+		hubs[device] = h
+		startStreamLoop(device, h)
+	}
+	return h
+}
+
+func streamInterval() time.Duration {
+	return envDuration("WG_STREAM_INTERVAL", defaultStreamInterval)
+}
+
+func startStreamLoop(device string, h *streamHub) {
+	go func() {
+		ticker := time.NewTicker(streamInterval())
+		defer ticker.Stop()
+
+		for range ticker.C {
+			status, err := clientStatus(device)
+			if err != nil {
+				continue
+			}
+			h.broadcast(status)
+		}
+	}()
+}
+
+// triggerStreamUpdate pushes an out-of-band snapshot to a device's
+// subscribers, used right after UpdatePeer mutates the interface so the UI
+// doesn't wait for the next tick.
+func triggerStreamUpdate(device string) {
+	hubsMu.Lock()
+	h, ok := hubs[device]
+	hubsMu.Unlock()
+	if !ok {
+		return
+	}
+
+	status, err := clientStatus(device)
+	if err != nil {
+		return
+	}
+	h.broadcast(status)
+}
+
+// readClientStatusStream upgrades to Server-Sent Events and pushes a JSON
+// snapshot of the device's client status every WG_STREAM_INTERVAL, plus an
+// immediate snapshot on subscribe and whenever UpdatePeer mutates the
+// device.
+func readClientStatusStream(c *gin.Context) {
+	device := c.Param("device")
+	if _, err := Get(device); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h := hubFor(device)
+	ch := h.subscribe()
+	defer h.unsubscribe(ch)
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+
+	if status, err := clientStatus(device); err == nil {
+		writeSSEEvent(c.Writer, status)
+		c.Writer.Flush()
+	}
+
+	for {
+		select {
+		case status, ok := <-ch:
+			if !ok {
+				return
+			}
+			writeSSEEvent(c.Writer, status)
+			c.Writer.Flush()
+		case <-c.Request.Context().Done():
+			return
+		}
+	}
+}
+
+func writeSSEEvent(w gin.ResponseWriter, status []*model.ClientStatus) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "data: %s\n\n", data)
+}
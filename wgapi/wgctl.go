@@ -1,6 +1,7 @@
 package wgapi
 
 import (
+	"fmt"
 	"net/http"
 	"os"
 	"sort"
@@ -19,8 +20,18 @@ func ApplyRoutes(r *gin.RouterGroup) {
 	g := r.Group("/status")
 	{
 		g.GET("/enabled", readEnabled)
-		g.GET("/interface", readInterfaceStatus)
-		g.GET("/clients", readClientStatus)
+		g.GET("/devices", readDevices)
+		g.GET("/metrics", readMetrics)
+		g.GET("/:device/interface", readInterfaceStatus)
+		g.GET("/:device/clients", readClientStatus)
+		g.GET("/:device/clients/stream", readClientStatusStream)
+		g.POST("/:device/clients/:pubkey/probe", readClientProbe)
+	}
+
+	s := r.Group("/server")
+	{
+		s.POST("/import", readServerImport)
+		s.POST("/keypair/rotate", readServerKeypairRotate)
 	}
 }
 
@@ -29,19 +40,58 @@ func readEnabled(c *gin.Context) {
 }
 
 func Enabeled() bool {
-	return os.Getenv("WG_DEVICE_NAME") != ""
+	return os.Getenv("WG_DEVICE_NAME") != "" || os.Getenv("WG_DEVICE_NAMES") != ""
 }
 
-func readInterfaceStatus(c *gin.Context) {
-	interfaceStatus := &model.InterfaceStatus{
-		Name:          "unknown",
-		DeviceType:    "unknown",
-		ListenPort:    0,
-		NumberOfPeers: 0,
-		PublicKey:     "",
-	}
-	var s, _ = New()
+// readDevices returns the interface status for every configured device.
+func readDevices(c *gin.Context) {
+	names, err := Devices()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Error("failed to read devices")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	statuses := make([]*model.InterfaceStatus, 0, len(names))
+	for _, name := range names {
+		status, err := interfaceStatus(name)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"err":    err,
+				"device": name,
+			}).Error("failed to read interface status")
+			continue
+		}
+		statuses = append(statuses, status)
+	}
+
+	c.JSON(http.StatusOK, statuses)
+}
+
+func interfaceStatus(device string) (*model.InterfaceStatus, error) {
+	s, err := Get(device)
+	if err != nil {
+		return nil, err
+	}
+
 	st, err := s.GetDeviceInfo()
+	if err != nil {
+		return nil, fmt.Errorf("could not read interface status: %w", err)
+	}
+
+	return &model.InterfaceStatus{
+		Name:          st.Device.Name,
+		DeviceType:    st.Device.Type,
+		ListenPort:    st.Device.ListenPort,
+		NumberOfPeers: st.Device.NumPeers,
+		PublicKey:     st.Device.PublicKey,
+	}, nil
+}
+
+func readInterfaceStatus(c *gin.Context) {
+	status, err := interfaceStatus(c.Param("device"))
 	if err != nil {
 		log.WithFields(log.Fields{
 			"err": err,
@@ -49,17 +99,12 @@ func readInterfaceStatus(c *gin.Context) {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
 		return
 	}
-	interfaceStatus.Name = st.Device.Name
-	interfaceStatus.DeviceType = st.Device.Type
-	interfaceStatus.ListenPort = st.Device.ListenPort
-	interfaceStatus.NumberOfPeers = st.Device.NumPeers
-	interfaceStatus.PublicKey = st.Device.PublicKey
 
-	c.JSON(http.StatusOK, interfaceStatus)
+	c.JSON(http.StatusOK, status)
 }
 
 func readClientStatus(c *gin.Context) {
-	status, err := clientStatus()
+	status, err := clientStatus(c.Param("device"))
 	if err != nil {
 		log.WithFields(log.Fields{
 			"err": err,
@@ -71,10 +116,26 @@ func readClientStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, status)
 }
 
-func clientStatus() ([]*model.ClientStatus, error) {
+// readClientProbe triggers an immediate liveness probe for a single peer and
+// returns the result synchronously, instead of waiting for the next
+// background probe tick.
+func readClientProbe(c *gin.Context) {
+	result, err := triggerProbe(c.Param("device"), c.Param("pubkey"))
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err": err,
+		}).Error("failed to probe client")
+		c.AbortWithStatusJSON(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func clientStatus(device string) ([]*model.ClientStatus, error) {
 	var clientStatus []*model.ClientStatus
 
-	var s, err = New()
+	s, err := Get(device)
 	if err != nil {
 		return clientStatus, err
 	}
@@ -90,8 +151,10 @@ func clientStatus() ([]*model.ClientStatus, error) {
 	for _, peer := range st.Peers {
 		peerHandshake := peer.LastHandshake
 		peerHandshakeRelative := time.Since(peerHandshake)
-		peerActive := peerHandshakeRelative.Minutes() < 3 // TODO: we need a better detection... ping for example?
+		peerActive, latency, jitter, loss := peerLiveness(device, peer.PublicKey, peerHandshake)
+
 		newClientStatus := &model.ClientStatus{
+			Device:                device,
 			PublicKey:             peer.PublicKey,
 			HasPresharedKey:       peer.HasPresharedKey,
 			ProtocolVersion:       peer.ProtocolVersion,
@@ -104,11 +167,14 @@ func clientStatus() ([]*model.ClientStatus, error) {
 			LastHandshakeRelative: peerHandshakeRelative,
 			ReceivedBytes:         int(peer.ReceiveBytes),
 			TransmittedBytes:      int(peer.TransmitBytes),
+			Latency:               latency,
+			Jitter:                jitter,
+			PacketLoss:            loss,
 		}
 
 		if withClientDetails {
 			for _, client := range clients {
-				if client.PublicKey != newClientStatus.PublicKey {
+				if client.Device != device || client.PublicKey != newClientStatus.PublicKey {
 					continue
 				}
 
@@ -129,7 +195,19 @@ func clientStatus() ([]*model.ClientStatus, error) {
 }
 
 func updatePeerCall(peer model.Peer, enable bool) (*model.Resp, error) {
-	var s, err = New()
+	device := peer.Device
+	if device == "" {
+		names, err := Devices()
+		if err != nil {
+			return nil, err
+		}
+		if len(names) != 1 {
+			return nil, Error{Message: "peer.Device is required when more than one WireGuard interface is configured"}
+		}
+		device = names[0]
+	}
+
+	s, err := Get(device)
 	if err != nil {
 		return nil, err
 	}
@@ -143,6 +221,7 @@ func updatePeerCall(peer model.Peer, enable bool) (*model.Resp, error) {
 		if err != nil {
 			return nil, err
 		}
+		triggerStreamUpdate(device)
 		return &model.Resp{OK: rsp.OK}, nil
 	}
 	rq := RemovePeerRequest{
@@ -152,6 +231,7 @@ func updatePeerCall(peer model.Peer, enable bool) (*model.Resp, error) {
 	if err != nil {
 		return nil, err
 	}
+	triggerStreamUpdate(device)
 	return &model.Resp{OK: rsp.OK}, nil
 }
 